@@ -0,0 +1,131 @@
+// Package diffcache provides a small LRU cache of diff.DiffResult keyed on the inputs that can
+// change it: the target manifest, the live object's resourceVersion, and the normalizer
+// configuration. Kubernetes bumps resourceVersion on every mutation of a live object and Argo CD
+// regenerates a new target hash on every manifest change, so staleness is never possible -
+// invalidation falls out of the key naturally. For installs with thousands of rarely-changing
+// resources per Application, this turns most comparisons into cache hits and skips the
+// (relatively expensive) structural diff entirely.
+package diffcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/argoproj/argo-cd/engine/pkg/utils/diff"
+)
+
+// Key identifies a cached diff.DiffResult. A single Cache is shared across every Application this
+// controller manages, potentially spanning many destination clusters, and resourceVersion is only
+// unique within one cluster's etcd - so the resource's own identity (destination server, GVK,
+// namespace/name) must be part of the key too, or two unrelated resources that happen to share a
+// resourceVersion and an (identically templated) target manifest would collide.
+type Key struct {
+	Server               string
+	Group                string
+	Kind                 string
+	Namespace            string
+	Name                 string
+	TargetHash           string
+	LiveResourceVersion  string
+	NormalizerConfigHash string
+}
+
+// HashTarget returns a stable hash of a target manifest suitable for use in a Key.
+func HashTarget(target *unstructured.Unstructured) string {
+	if target == nil {
+		return "<nil>"
+	}
+	return hashObj(target.Object)
+}
+
+// HashNormalizerConfig returns a stable hash of any normalizer configuration value (e.g. the
+// diffNormalizer currently in effect for the Application), suitable for use in a Key.
+func HashNormalizerConfig(config interface{}) string {
+	return hashObj(config)
+}
+
+func hashObj(v interface{}) string {
+	// best effort: a marshal failure just means this entry never hits the cache.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+type entry struct {
+	key    Key
+	result diff.DiffResult
+}
+
+// Cache is an LRU of diff.DiffResult, safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[Key]*list.Element
+
+	hits   int64
+	misses int64
+}
+
+// NewCache creates a Cache that evicts its least-recently-used entry once more than capacity
+// entries are stored. A capacity of zero or less never evicts.
+func NewCache(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[Key]*list.Element),
+	}
+}
+
+// Get returns the cached DiffResult for key, if present, marking it most-recently-used.
+func (c *Cache) Get(key Key) (diff.DiffResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return diff.DiffResult{}, false
+	}
+	c.hits++
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry).result, true
+}
+
+// Add stores result under key, evicting the least-recently-used entry if the cache is full.
+func (c *Cache) Add(key Key, result diff.DiffResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry).result = result
+		c.ll.MoveToFront(el)
+		return
+	}
+	el := c.ll.PushFront(&entry{key: key, result: result})
+	c.items[key] = el
+	if c.capacity > 0 && c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*entry).key)
+		}
+	}
+}
+
+// Stats returns the current entry count and the lifetime hit ratio (hits / (hits+misses)).
+func (c *Cache) Stats() (size int, hitRatio float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := c.hits + c.misses
+	if total == 0 {
+		return c.ll.Len(), 0
+	}
+	return c.ll.Len(), float64(c.hits) / float64(total)
+}
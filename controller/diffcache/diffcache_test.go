@@ -0,0 +1,101 @@
+package diffcache
+
+import (
+	"testing"
+
+	"github.com/argoproj/argo-cd/engine/pkg/utils/diff"
+)
+
+func TestCacheGetMissThenHit(t *testing.T) {
+	c := NewCache(10)
+	key := Key{Server: "https://a", Group: "apps", Kind: "Deployment", Namespace: "ns", Name: "web", TargetHash: "th", LiveResourceVersion: "1"}
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	want := diff.DiffResult{Modified: true}
+	c.Add(key, want)
+
+	got, ok := c.Get(key)
+	if !ok {
+		t.Fatal("expected a hit after Add")
+	}
+	if got.Modified != want.Modified {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache(2)
+	k1 := Key{Name: "a", TargetHash: "1"}
+	k2 := Key{Name: "b", TargetHash: "2"}
+	k3 := Key{Name: "c", TargetHash: "3"}
+
+	c.Add(k1, diff.DiffResult{})
+	c.Add(k2, diff.DiffResult{})
+	// touch k1 so it's now more recently used than k2
+	if _, ok := c.Get(k1); !ok {
+		t.Fatal("expected k1 to be present")
+	}
+	// adding k3 should evict k2 (least recently used), not k1
+	c.Add(k3, diff.DiffResult{})
+
+	if _, ok := c.Get(k2); ok {
+		t.Fatal("expected k2 to have been evicted")
+	}
+	if _, ok := c.Get(k1); !ok {
+		t.Fatal("expected k1 to still be present")
+	}
+	if _, ok := c.Get(k3); !ok {
+		t.Fatal("expected k3 to be present")
+	}
+}
+
+func TestCacheDistinguishesResourcesByIdentityNotJustHashAndVersion(t *testing.T) {
+	c := NewCache(10)
+	// two resources on different clusters (or coincidentally sharing a resourceVersion and an
+	// identical templated manifest) must never collide in the cache.
+	keyClusterA := Key{Server: "https://cluster-a", Group: "", Kind: "ConfigMap", Namespace: "ns", Name: "cm", TargetHash: "same-hash", LiveResourceVersion: "42"}
+	keyClusterB := Key{Server: "https://cluster-b", Group: "", Kind: "ConfigMap", Namespace: "ns", Name: "cm", TargetHash: "same-hash", LiveResourceVersion: "42"}
+
+	c.Add(keyClusterA, diff.DiffResult{Modified: false})
+	c.Add(keyClusterB, diff.DiffResult{Modified: true})
+
+	gotA, ok := c.Get(keyClusterA)
+	if !ok || gotA.Modified {
+		t.Fatalf("expected cluster A's own (unmodified) entry, got ok=%v %+v", ok, gotA)
+	}
+	gotB, ok := c.Get(keyClusterB)
+	if !ok || !gotB.Modified {
+		t.Fatalf("expected cluster B's own (modified) entry, got ok=%v %+v", ok, gotB)
+	}
+}
+
+func TestCacheStatsHitRatio(t *testing.T) {
+	c := NewCache(10)
+	key := Key{Name: "a"}
+
+	if size, ratio := c.Stats(); size != 0 || ratio != 0 {
+		t.Fatalf("expected empty stats, got size=%d ratio=%f", size, ratio)
+	}
+
+	c.Get(key) // miss
+	c.Add(key, diff.DiffResult{})
+	c.Get(key) // hit
+	c.Get(key) // hit
+
+	size, ratio := c.Stats()
+	if size != 1 {
+		t.Fatalf("expected size 1, got %d", size)
+	}
+	if ratio != float64(2)/float64(3) {
+		t.Fatalf("expected hit ratio 2/3, got %f", ratio)
+	}
+}
+
+func TestHashTargetNilIsStable(t *testing.T) {
+	if HashTarget(nil) != HashTarget(nil) {
+		t.Fatal("expected HashTarget(nil) to be stable")
+	}
+}
@@ -0,0 +1,171 @@
+// Package driftdetector runs "is the live state drifted from git?" as an independent subsystem
+// with its own schedule, decoupled from the sync operation path. A full getRepoObjs + DiffArray
+// pass is not cheap, and for installs with thousands of Applications there is no reason to pay
+// that cost on every reconciliation loop tick when operations themselves poll far more often
+// (seconds) than drift realistically changes (minutes). The detector owns a small worker pool,
+// reuses the existing liveStateCache rather than re-fetching live state, and publishes the
+// resulting diffs as a snapshot any caller (AppStateManager, notifications, the UI) can read
+// without re-running the comparison itself.
+package driftdetector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-cd/engine/pkg/utils/diff"
+	kubesync "github.com/argoproj/argo-cd/engine/pkg/utils/kube/sync"
+	"github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
+)
+
+// DriftType classifies how a managed resource differs from the cached live state.
+type DriftType string
+
+const (
+	DriftAdded    DriftType = "added"
+	DriftRemoved  DriftType = "removed"
+	DriftModified DriftType = "modified"
+)
+
+// DriftEvent describes a single resource that moved in or out of sync for an Application.
+type DriftEvent struct {
+	AppName   string
+	Group     string
+	Version   string
+	Kind      string
+	Namespace string
+	Name      string
+	Type      DriftType
+}
+
+// Snapshot is the result of the most recent drift computation for one Application.
+type Snapshot struct {
+	Revision string
+	// Source is the ApplicationSource the snapshot was computed against (app.Spec.Source at
+	// compute time), recorded so a caller comparing against a syncOp.Source override can tell
+	// the snapshot doesn't apply rather than reusing it just because Revision happens to match.
+	Source v1alpha1.ApplicationSource
+	// LiveResourceVersions records, per managed resource key (kube.GetResourceKey(obj).String()),
+	// the live resourceVersion observed when this snapshot was computed. A caller must confirm
+	// every resourceVersion it currently sees matches before reusing DiffResults - resourceVersion
+	// is the only reliable signal that the live object set hasn't changed (created/deleted/
+	// mutated) since this snapshot was taken, up to interval-many minutes ago.
+	LiveResourceVersions map[string]string
+	ComputedAt           time.Time
+	Reconciliation       kubesync.ReconciliationResult
+	// DiffResults maps each managed resource's identity key (kube.GetResourceKey(obj).String(),
+	// using whichever of target/live is present) to the diff.DiffResult computed for it. Keying
+	// by identity rather than by position lets a reuser merge these results against its own,
+	// independently-computed sync.Reconcile output without assuming the two reconciliations
+	// produced their Target/Live slices in the same order - sync.Reconcile is fed a Go map of
+	// live objects, so ordering is not guaranteed to match across calls.
+	DiffResults map[string]diff.DiffResult
+	Events      []DriftEvent
+}
+
+// ComputeFunc performs the actual getRepoObjs/DiffArray work for a single app and returns the
+// resulting Snapshot. It is supplied by the caller (controller.appStateManager) to avoid an
+// import cycle between this package and controller.
+type ComputeFunc func(ctx context.Context, appName string) (*Snapshot, error)
+
+// ListFunc returns the names of Applications currently owned by this controller replica.
+type ListFunc func() []string
+
+// Detector periodically recomputes drift for every app returned by ListFunc, spreading the work
+// across a fixed-size worker pool, and keeps the latest Snapshot per app available for readers.
+type Detector struct {
+	interval time.Duration
+	workers  int
+	compute  ComputeFunc
+	list     ListFunc
+
+	mu        sync.RWMutex
+	snapshots map[string]*Snapshot
+
+	events chan DriftEvent
+}
+
+// NewDetector creates a Detector that recomputes drift for all apps returned by list every
+// interval, using workers goroutines to process apps concurrently. events, if non-nil, receives
+// every DriftEvent produced so notifications/UI consumers can subscribe; callers that don't need
+// the stream can pass a nil channel and just poll Latest.
+func NewDetector(interval time.Duration, workers int, list ListFunc, compute ComputeFunc, events chan DriftEvent) *Detector {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Detector{
+		interval:  interval,
+		workers:   workers,
+		list:      list,
+		compute:   compute,
+		snapshots: make(map[string]*Snapshot),
+		events:    events,
+	}
+}
+
+// Run blocks, recomputing drift for all apps every d.interval until ctx is cancelled.
+func (d *Detector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+func (d *Detector) runOnce(ctx context.Context) {
+	apps := d.list()
+	appCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for appName := range appCh {
+				d.refresh(ctx, appName)
+			}
+		}()
+	}
+	for _, appName := range apps {
+		select {
+		case appCh <- appName:
+		case <-ctx.Done():
+			close(appCh)
+			wg.Wait()
+			return
+		}
+	}
+	close(appCh)
+	wg.Wait()
+}
+
+func (d *Detector) refresh(ctx context.Context, appName string) {
+	snap, err := d.compute(ctx, appName)
+	if err != nil || snap == nil {
+		return
+	}
+	d.mu.Lock()
+	d.snapshots[appName] = snap
+	d.mu.Unlock()
+	if d.events != nil {
+		for _, ev := range snap.Events {
+			select {
+			case d.events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Latest returns the most recently computed Snapshot for appName, if one exists.
+func (d *Detector) Latest(appName string) (*Snapshot, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	snap, ok := d.snapshots[appName]
+	return snap, ok
+}
@@ -0,0 +1,76 @@
+package driftdetector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDetectorRefreshesEveryAppAndPublishesEvents(t *testing.T) {
+	apps := []string{"app-a", "app-b", "app-c"}
+	list := func() []string { return apps }
+
+	var mu sync.Mutex
+	computed := make(map[string]int)
+	compute := func(ctx context.Context, appName string) (*Snapshot, error) {
+		mu.Lock()
+		computed[appName]++
+		mu.Unlock()
+		return &Snapshot{
+			Revision: "rev-" + appName,
+			Events:   []DriftEvent{{AppName: appName, Type: DriftModified}},
+		}, nil
+	}
+
+	events := make(chan DriftEvent, len(apps))
+	d := NewDetector(time.Hour, 2, list, compute, events)
+	d.runOnce(context.Background())
+
+	for _, app := range apps {
+		snap, ok := d.Latest(app)
+		if !ok {
+			t.Fatalf("expected a snapshot for %s", app)
+		}
+		if snap.Revision != "rev-"+app {
+			t.Fatalf("unexpected revision for %s: %s", app, snap.Revision)
+		}
+	}
+
+	close(events)
+	var seen []string
+	for ev := range events {
+		seen = append(seen, ev.AppName)
+	}
+	sort.Strings(seen)
+	if len(seen) != len(apps) {
+		t.Fatalf("expected %d events, got %d", len(apps), len(seen))
+	}
+}
+
+func TestDetectorSkipsAppOnComputeError(t *testing.T) {
+	list := func() []string { return []string{"broken", "ok"} }
+	compute := func(ctx context.Context, appName string) (*Snapshot, error) {
+		if appName == "broken" {
+			return nil, context.DeadlineExceeded
+		}
+		return &Snapshot{Revision: "rev"}, nil
+	}
+	d := NewDetector(time.Hour, 1, list, compute, nil)
+	d.runOnce(context.Background())
+
+	if _, ok := d.Latest("broken"); ok {
+		t.Fatal("expected no snapshot to be stored for a failed compute")
+	}
+	if _, ok := d.Latest("ok"); !ok {
+		t.Fatal("expected a snapshot for the app that computed successfully")
+	}
+}
+
+func TestNewDetectorClampsWorkersToOne(t *testing.T) {
+	d := NewDetector(time.Minute, 0, func() []string { return nil }, nil, nil)
+	if d.workers != 1 {
+		t.Fatalf("expected workers to be clamped to 1, got %d", d.workers)
+	}
+}
@@ -0,0 +1,122 @@
+package healthplugin
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/argoproj/argo-cd/engine/pkg/utils/health"
+)
+
+func widget(generation, observedGeneration int64, phase string, conditions ...map[string]interface{}) *unstructured.Unstructured {
+	status := map[string]interface{}{
+		"phase": phase,
+	}
+	if observedGeneration != 0 {
+		status["observedGeneration"] = observedGeneration
+	}
+	if len(conditions) > 0 {
+		raw := make([]interface{}, len(conditions))
+		for i, c := range conditions {
+			raw[i] = c
+		}
+		status["conditions"] = raw
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":       "thing",
+			"generation": generation,
+		},
+		"status": status,
+	}}
+}
+
+func widgetGK() schema.GroupKind {
+	return schema.GroupKind{Group: "example.com", Kind: "Widget"}
+}
+
+func TestEvaluateConditionTrue(t *testing.T) {
+	e, err := NewEvaluator([]Plugin{{GroupKind: widgetGK(), Expr: `conditionTrue("Ready") ? "Healthy" : "Progressing"`}})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	obj := widget(1, 1, "", map[string]interface{}{"type": "Ready", "status": "True"})
+	status, handled, err := e.Evaluate(obj)
+	if err != nil {
+		t.Fatalf("unexpected eval error: %v", err)
+	}
+	if !handled {
+		t.Fatal("expected plugin to handle this GroupKind")
+	}
+	if status != health.HealthStatusHealthy {
+		t.Fatalf("expected Healthy, got %s", status)
+	}
+}
+
+func TestEvaluateObservedGenerationAndPhase(t *testing.T) {
+	e, err := NewEvaluator([]Plugin{{GroupKind: widgetGK(), Expr: `observedGenerationCurrent() && phase() == "Bound" ? "Healthy" : "Progressing"`}})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	current := widget(2, 2, "Bound")
+	status, handled, err := e.Evaluate(current)
+	if err != nil || !handled {
+		t.Fatalf("unexpected result: status=%v handled=%v err=%v", status, handled, err)
+	}
+	if status != health.HealthStatusHealthy {
+		t.Fatalf("expected Healthy, got %s", status)
+	}
+
+	stale := widget(2, 1, "Bound")
+	status, _, err = e.Evaluate(stale)
+	if err != nil {
+		t.Fatalf("unexpected eval error: %v", err)
+	}
+	if status != health.HealthStatusProgressing {
+		t.Fatalf("expected Progressing for stale generation, got %s", status)
+	}
+}
+
+func TestEvaluateUnregisteredGroupKindNotHandled(t *testing.T) {
+	e, err := NewEvaluator([]Plugin{{GroupKind: widgetGK(), Expr: `"Healthy"`}})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	other := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm"},
+	}}
+	_, handled, err := e.Evaluate(other)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handled {
+		t.Fatal("expected no plugin to be registered for ConfigMap")
+	}
+}
+
+func TestEvaluateUnrecognizedClassificationErrors(t *testing.T) {
+	e, err := NewEvaluator([]Plugin{{GroupKind: widgetGK(), Expr: `"NotARealStatus"`}})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	_, handled, err := e.Evaluate(widget(1, 1, ""))
+	if !handled {
+		t.Fatal("expected the registered plugin to be considered handled even on error")
+	}
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized classification")
+	}
+}
+
+func TestNewEvaluatorRejectsBadExpression(t *testing.T) {
+	_, err := NewEvaluator([]Plugin{{GroupKind: widgetGK(), Expr: `this is not valid CEL (((`}})
+	if err == nil {
+		t.Fatal("expected a compile error for an invalid expression")
+	}
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// comparisonPhaseDuration replaces the old practice of logging a per-call timings map: a
+// histogram, labeled by phase, lets operators see phase-level latency distributions (e.g. p99
+// git_ms) across every Application over time, not just inspect one log line at a time.
+var comparisonPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "argocd",
+	Subsystem: "app_controller",
+	Name:      "comparison_phase_duration_seconds",
+	Help:      "Duration of each phase of CompareAppState, labeled by phase name.",
+}, []string{"phase"})
+
+func init() {
+	prometheus.MustRegister(comparisonPhaseDuration)
+}
+
+// ObserveComparisonPhaseDuration records how long one phase of a single CompareAppState call
+// took.
+func (m *MetricsServer) ObserveComparisonPhaseDuration(phase string, duration time.Duration) {
+	comparisonPhaseDuration.WithLabelValues(phase).Observe(duration.Seconds())
+}
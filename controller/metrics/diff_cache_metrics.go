@@ -0,0 +1,31 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// diffCacheSize and diffCacheHitRatio track controller/diffcache.Cache's effectiveness across the
+// whole process, so operators can size defaultDiffCacheSize from real hit-ratio data instead of
+// guessing.
+var (
+	diffCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "argocd",
+		Subsystem: "app_controller",
+		Name:      "diff_cache_entries",
+		Help:      "Number of entries currently held in the diff result cache.",
+	})
+	diffCacheHitRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "argocd",
+		Subsystem: "app_controller",
+		Name:      "diff_cache_hit_ratio",
+		Help:      "Lifetime hit ratio (hits / (hits+misses)) of the diff result cache.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(diffCacheSize, diffCacheHitRatio)
+}
+
+// SetDiffCacheStats records the diff cache's current entry count and lifetime hit ratio.
+func (m *MetricsServer) SetDiffCacheStats(size int, hitRatio float64) {
+	diffCacheSize.Set(float64(size))
+	diffCacheHitRatio.Set(hitRatio)
+}
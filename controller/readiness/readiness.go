@@ -0,0 +1,210 @@
+// Package readiness implements a Helm-v3-style "comprehensive" readiness check: given a set of
+// already-fetched live objects, it decides whether each one has reached a usable steady state.
+// Unlike the regular health assessment (util/health), which classifies a resource into
+// Healthy/Progressing/Degraded/Suspended/Missing for display purposes, readiness is a simple
+// boolean gate meant to block an in-progress sync operation until the resources it touched are
+// actually serving traffic/ready for use.
+package readiness
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Predicate reports whether obj has reached a ready steady state, along with a human-readable
+// reason when it has not. Predicates never make API calls; they only inspect the object passed
+// to them.
+type Predicate func(obj *unstructured.Unstructured) (ready bool, reason string)
+
+// predicates maps a "Group/Kind" to the Predicate responsible for it. Kinds with no registered
+// predicate are treated as ready, matching the Helm v3 `--wait` behavior of only gating on a
+// known set of built-in types.
+var predicates = map[string]Predicate{
+	"apps/Deployment":        deploymentReady,
+	"apps/StatefulSet":       statefulSetReady,
+	"apps/DaemonSet":         daemonSetReady,
+	"batch/Job":              jobReady,
+	"/Pod":                   podReady,
+	"/PersistentVolumeClaim": pvcReady,
+	"/Service":               serviceReady,
+	"apiextensions.k8s.io/CustomResourceDefinition": crdReady,
+}
+
+// IsReady dispatches obj to the predicate registered for its GroupKind. Unknown kinds are
+// considered ready since Argo CD has no way to reason about their readiness.
+func IsReady(obj *unstructured.Unstructured) (bool, string) {
+	gvk := obj.GroupVersionKind()
+	predicate, ok := predicates[gvk.Group+"/"+gvk.Kind]
+	if !ok {
+		return true, ""
+	}
+	return predicate(obj)
+}
+
+// AllReady evaluates IsReady against every non-nil object in objs and returns false along with
+// one reason for each resource that is not yet ready.
+func AllReady(objs []*unstructured.Unstructured) (bool, []string) {
+	allReady := true
+	var reasons []string
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		ready, reason := IsReady(obj)
+		if !ready {
+			allReady = false
+			reasons = append(reasons, fmt.Sprintf("%s/%s %s: %s", obj.GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), reason))
+		}
+	}
+	return allReady, reasons
+}
+
+func deploymentReady(obj *unstructured.Unstructured) (bool, string) {
+	var d appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &d); err != nil {
+		return true, ""
+	}
+	if d.Generation != d.Status.ObservedGeneration {
+		return false, "waiting for rollout to be observed"
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d new replicas updated", d.Status.UpdatedReplicas, replicas)
+	}
+	if d.Status.AvailableReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, replicas)
+	}
+	if d.Status.UnavailableReplicas > 0 {
+		return false, fmt.Sprintf("%d replicas unavailable", d.Status.UnavailableReplicas)
+	}
+	return true, ""
+}
+
+func statefulSetReady(obj *unstructured.Unstructured) (bool, string) {
+	var s appsv1.StatefulSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &s); err != nil {
+		return true, ""
+	}
+	if s.Generation != s.Status.ObservedGeneration {
+		return false, "waiting for rollout to be observed"
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	if s.Status.UpdatedReplicas != replicas {
+		return false, fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, replicas)
+	}
+	if s.Status.ReadyReplicas != replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", s.Status.ReadyReplicas, replicas)
+	}
+	if s.Spec.UpdateStrategy.RollingUpdate != nil && s.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		if s.Status.UpdateRevision != s.Status.CurrentRevision {
+			return false, "waiting for partitioned rolling update to complete"
+		}
+	}
+	return true, ""
+}
+
+func daemonSetReady(obj *unstructured.Unstructured) (bool, string) {
+	var ds appsv1.DaemonSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds); err != nil {
+		return true, ""
+	}
+	if ds.Status.NumberReady != ds.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d of %d pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+	}
+	if ds.Status.UpdatedNumberScheduled != ds.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("%d of %d pods updated", ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+	}
+	return true, ""
+}
+
+func jobReady(obj *unstructured.Unstructured) (bool, string) {
+	var j batchv1.Job
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &j); err != nil {
+		return true, ""
+	}
+	for _, cond := range j.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true, ""
+		}
+	}
+	if j.Spec.Completions != nil && j.Status.Succeeded >= *j.Spec.Completions {
+		return true, ""
+	}
+	return false, "job has not completed"
+}
+
+func podReady(obj *unstructured.Unstructured) (bool, string) {
+	var p corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &p); err != nil {
+		return true, ""
+	}
+	if p.DeletionTimestamp != nil {
+		return false, "pod is terminating"
+	}
+	for _, cond := range p.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			if cond.Status == corev1.ConditionTrue {
+				return true, ""
+			}
+			return false, "pod is not ready"
+		}
+	}
+	return false, "pod has no Ready condition yet"
+}
+
+func pvcReady(obj *unstructured.Unstructured) (bool, string) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pvc); err != nil {
+		return true, ""
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, fmt.Sprintf("phase is %s", pvc.Status.Phase)
+	}
+	return true, ""
+}
+
+func serviceReady(obj *unstructured.Unstructured) (bool, string) {
+	var svc corev1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &svc); err != nil {
+		return true, ""
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return true, ""
+	}
+	if len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return false, "waiting for load balancer ingress"
+	}
+	return true, ""
+}
+
+func crdReady(obj *unstructured.Unstructured) (bool, string) {
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &crd); err != nil {
+		return true, ""
+	}
+	established := false
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
+			established = true
+		}
+		if cond.Type == apiextensionsv1.NamesAccepted && cond.Status == apiextensionsv1.ConditionFalse {
+			return false, "names not accepted"
+		}
+	}
+	if !established {
+		return false, "not yet established"
+	}
+	return true, ""
+}
@@ -0,0 +1,135 @@
+package readiness
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func deployment(generation, observedGeneration int64, replicas, updated, available, unavailable int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":       "web",
+			"namespace":  "default",
+			"generation": generation,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"observedGeneration":  observedGeneration,
+			"updatedReplicas":     updated,
+			"availableReplicas":   available,
+			"unavailableReplicas": unavailable,
+		},
+	}}
+}
+
+func TestDeploymentReady(t *testing.T) {
+	ready, _ := IsReady(deployment(2, 2, 3, 3, 3, 0))
+	if !ready {
+		t.Fatal("expected fully rolled out deployment to be ready")
+	}
+}
+
+func TestDeploymentNotReadyStaleGeneration(t *testing.T) {
+	ready, reason := IsReady(deployment(2, 1, 3, 3, 3, 0))
+	if ready {
+		t.Fatal("expected deployment with unobserved generation to not be ready")
+	}
+	if reason == "" {
+		t.Fatal("expected a reason")
+	}
+}
+
+func TestDeploymentNotReadyUnavailable(t *testing.T) {
+	ready, _ := IsReady(deployment(2, 2, 3, 3, 2, 1))
+	if ready {
+		t.Fatal("expected deployment with unavailable replicas to not be ready")
+	}
+}
+
+func statefulSet(generation, observedGeneration, replicas, updated, ready int64, partition *int64, updateRevision, currentRevision string) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata": map[string]interface{}{
+			"name":       "db",
+			"namespace":  "default",
+			"generation": generation,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": observedGeneration,
+			"updatedReplicas":    updated,
+			"readyReplicas":      ready,
+			"updateRevision":     updateRevision,
+			"currentRevision":    currentRevision,
+		},
+	}
+	if partition != nil {
+		obj["spec"].(map[string]interface{})["updateStrategy"] = map[string]interface{}{
+			"rollingUpdate": map[string]interface{}{
+				"partition": *partition,
+			},
+		}
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestStatefulSetReady(t *testing.T) {
+	ready, _ := IsReady(statefulSet(1, 1, 3, 3, 3, nil, "rev-a", "rev-a"))
+	if !ready {
+		t.Fatal("expected fully rolled out statefulset to be ready")
+	}
+}
+
+func TestStatefulSetPartitionedRolloutWaits(t *testing.T) {
+	partition := int64(1)
+	obj := statefulSet(1, 1, 3, 3, 3, &partition, "rev-b", "rev-a")
+	ready, reason := IsReady(obj)
+	if ready {
+		t.Fatal("expected a partitioned rollout with mismatched revisions to not be ready")
+	}
+	if reason == "" {
+		t.Fatal("expected a reason")
+	}
+}
+
+func TestStatefulSetPartitionedRolloutComplete(t *testing.T) {
+	partition := int64(1)
+	obj := statefulSet(1, 1, 3, 3, 3, &partition, "rev-a", "rev-a")
+	ready, _ := IsReady(obj)
+	if !ready {
+		t.Fatal("expected a partitioned rollout with matching revisions to be ready")
+	}
+}
+
+func TestUnknownKindIsReady(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name": "thing",
+		},
+	}}
+	ready, reason := IsReady(obj)
+	if !ready || reason != "" {
+		t.Fatalf("expected unknown kind to be treated as ready, got ready=%v reason=%q", ready, reason)
+	}
+}
+
+func TestAllReadyCollectsReasonsAndSkipsNil(t *testing.T) {
+	notReady := deployment(2, 1, 3, 0, 0, 0)
+	allReady, reasons := AllReady([]*unstructured.Unstructured{nil, deployment(1, 1, 1, 1, 1, 0), notReady})
+	if allReady {
+		t.Fatal("expected AllReady to be false when one resource is not ready")
+	}
+	if len(reasons) != 1 {
+		t.Fatalf("expected exactly one reason, got %v", reasons)
+	}
+}
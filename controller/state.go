@@ -2,20 +2,31 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+	stdsync "sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/yudai/gojsondiff"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/argoproj/argo-cd/common"
 	statecache "github.com/argoproj/argo-cd/controller/cache"
+	"github.com/argoproj/argo-cd/controller/diffcache"
+	"github.com/argoproj/argo-cd/controller/driftdetector"
+	"github.com/argoproj/argo-cd/controller/healthplugin"
 	"github.com/argoproj/argo-cd/controller/metrics"
+	"github.com/argoproj/argo-cd/controller/readiness"
 	"github.com/argoproj/argo-cd/engine/pkg/utils/diff"
 	"github.com/argoproj/argo-cd/engine/pkg/utils/health"
 	"github.com/argoproj/argo-cd/engine/pkg/utils/io"
@@ -57,7 +68,14 @@ func GetLiveObjs(res []managedResource) []*unstructured.Unstructured {
 
 // AppStateManager defines methods which allow to compare application spec and actual application state.
 type AppStateManager interface {
-	CompareAppState(app *v1alpha1.Application, project *appv1.AppProject, revision string, source v1alpha1.ApplicationSource, noCache bool, localObjects []string) *comparisonResult
+	// CompareAppState returns nil, without touching app.Status, when app is not owned by this
+	// controller replica's shard (see IsManagedApp) - callers must not persist a status in that
+	// case, since doing so would clobber the real status written by the owning replica.
+	// checkReadiness gates the comprehensive, Helm-v3-style readiness check (see
+	// controller/readiness): it is opt-in because it converts every managed live object from
+	// unstructured to typed, and callers that only need sync/health status (i.e. every poll that
+	// isn't blocking on Wait=true) should leave it false.
+	CompareAppState(app *v1alpha1.Application, project *appv1.AppProject, revision string, source v1alpha1.ApplicationSource, noCache bool, localObjects []string, checkReadiness bool) *comparisonResult
 	SyncAppState(app *v1alpha1.Application, state *v1alpha1.OperationState)
 }
 
@@ -69,8 +87,12 @@ type comparisonResult struct {
 	reconciliationResult sync.ReconciliationResult
 	diffNormalizer       diff.Normalizer
 	appSourceType        v1alpha1.ApplicationSourceType
-	// timings maps phases of comparison to the duration it took to complete (for statistical purposes)
-	timings map[string]time.Duration
+	// resourcesProgressing is true when one or more managedResources have not yet passed the
+	// comprehensive, Helm-v3-style readiness check (see controller/readiness). It is the basis
+	// for the HealthStatusProgressing sub-state consulted by SyncAppState's wait gate.
+	resourcesProgressing bool
+	// progressingMessages explains, per not-yet-ready resource, why it is still progressing.
+	progressingMessages []string
 }
 
 // appStateManager allows to compare applications to git
@@ -84,6 +106,175 @@ type appStateManager struct {
 	repoClientset  apiclient.Clientset
 	liveStateCache statecache.LiveStateCache
 	namespace      string
+	// shardSelector, when non-nil, restricts this manager to Applications whose labels
+	// match the selector, allowing multiple controller replicas to shard a single
+	// Application CR between them (e.g. `argocd.argoproj.io/shard=a,!foo/controller`).
+	shardSelector labels.Selector
+	// driftDetector, when set, runs drift detection on its own cadence independent of the sync
+	// operation path. CompareAppState consults it first and only falls back to an inline
+	// getRepoObjs+DiffArray pass when no fresh snapshot is available.
+	driftDetector *driftdetector.Detector
+	// diffCache holds per-(target, live) diff.DiffResults so unchanged pairs skip re-diffing on
+	// every comparison; see controller/diffcache.
+	diffCache *diffcache.Cache
+
+	// healthPluginMu guards healthPluginHash/healthPluginEvaluator so getHealthPluginEvaluator
+	// can safely cache the compiled healthplugin.Evaluator across concurrent CompareAppState
+	// calls and only recompile it when the resource.health.plugins.* entries of argocd-cm change.
+	healthPluginMu        stdsync.Mutex
+	healthPluginHash      string
+	healthPluginEvaluator *healthplugin.Evaluator
+}
+
+// defaultDiffCacheSize bounds the number of (target, live) diff results appStateManager keeps
+// around. At roughly a few KB per cached diff.DiffResult, this comfortably covers installs with
+// tens of thousands of managed resources without unbounded memory growth.
+const defaultDiffCacheSize = 50000
+
+// diffArrayCached is a drop-in replacement for diff.DiffArray that first consults m.diffCache,
+// keyed on (destination server, GVK, namespace/name, target hash, live resourceVersion,
+// normalizer config hash), and only calls diff.DiffArray for the subset of pairs that miss.
+// Results are merged back in the original order so callers can't tell the difference. server must
+// identify the destination cluster, since a bare resourceVersion is only unique within one
+// cluster's etcd.
+func (m *appStateManager) diffArrayCached(targets, lives []*unstructured.Unstructured, normalizer diff.Normalizer, server string) (*diff.DiffResultList, error) {
+	if m.diffCache == nil {
+		return diff.DiffArray(targets, lives, normalizer)
+	}
+
+	normalizerHash := diffcache.HashNormalizerConfig(normalizer)
+	keys := make([]diffcache.Key, len(targets))
+	results := make([]diff.DiffResult, len(targets))
+
+	missIdx := make([]int, 0)
+	missTargets := make([]*unstructured.Unstructured, 0)
+	missLives := make([]*unstructured.Unstructured, 0)
+
+	for i, target := range targets {
+		live := lives[i]
+		liveResourceVersion := ""
+		if live != nil {
+			liveResourceVersion = live.GetResourceVersion()
+		}
+		// identify the resource itself from whichever of target/live is present; at least one
+		// always is, since sync.Reconcile only ever pairs a target with a live object when one of
+		// the two actually exists.
+		identityObj := target
+		if identityObj == nil {
+			identityObj = live
+		}
+		var gvk schema.GroupVersionKind
+		namespace, name := "", ""
+		if identityObj != nil {
+			gvk = identityObj.GroupVersionKind()
+			namespace = identityObj.GetNamespace()
+			name = identityObj.GetName()
+		}
+		key := diffcache.Key{
+			Server:               server,
+			Group:                gvk.Group,
+			Kind:                 gvk.Kind,
+			Namespace:            namespace,
+			Name:                 name,
+			TargetHash:           diffcache.HashTarget(target),
+			LiveResourceVersion:  liveResourceVersion,
+			NormalizerConfigHash: normalizerHash,
+		}
+		keys[i] = key
+		// an empty hash means marshalling failed: such a pair is always re-diffed and never cached
+		if key.TargetHash != "" && key.NormalizerConfigHash != "" {
+			if cached, ok := m.diffCache.Get(key); ok {
+				results[i] = cached
+				continue
+			}
+		}
+		missIdx = append(missIdx, i)
+		missTargets = append(missTargets, target)
+		missLives = append(missLives, live)
+	}
+
+	if len(missIdx) > 0 {
+		missResults, err := diff.DiffArray(missTargets, missLives, normalizer)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range missIdx {
+			results[i] = missResults.Diffs[j]
+			if keys[i].TargetHash != "" && keys[i].NormalizerConfigHash != "" {
+				m.diffCache.Add(keys[i], results[i])
+			}
+		}
+	}
+
+	if m.metricsServer != nil {
+		size, hitRatio := m.diffCache.Stats()
+		m.metricsServer.SetDiffCacheStats(size, hitRatio)
+	}
+
+	return &diff.DiffResultList{Diffs: results}, nil
+}
+
+// IsManagedApp returns whether the application is owned by this controller replica, i.e. its
+// labels satisfy the configured shard selector. A manager with no shard selector configured
+// owns every Application.
+func (m *appStateManager) IsManagedApp(app *v1alpha1.Application) bool {
+	if m.shardSelector == nil {
+		return true
+	}
+	return m.shardSelector.Matches(labels.Set(app.GetLabels()))
+}
+
+// ApplicationListOptionsFunc returns a ListOptions tweak that narrows an Applications().List or
+// the Application SharedIndexInformer's ListWatch to this manager's shard selector, so that a
+// sharded replica using it only receives the Applications it owns - the server-side LabelSelector
+// does the actual work; FilterByShard below is the client-side fallback for code paths (like
+// StartDriftDetector's listing) that want a defense-in-depth re-check after the list comes back.
+// StartDriftDetector's listing is its only caller in this package.
+func (m *appStateManager) ApplicationListOptionsFunc() func(options *metav1.ListOptions) {
+	return func(options *metav1.ListOptions) {
+		if m.shardSelector != nil && !m.shardSelector.Empty() {
+			options.LabelSelector = m.shardSelector.String()
+		}
+	}
+}
+
+// FilterByShard returns the subset of apps whose labels match selector. A nil or empty selector
+// matches everything. It is intended to be applied both to informer event handlers and to the
+// results of direct Applications().List calls so that horizontally sharded controller replicas
+// each observe a disjoint slice of Applications.
+func FilterByShard(apps []*v1alpha1.Application, selector labels.Selector) []*v1alpha1.Application {
+	if selector == nil || selector.Empty() {
+		return apps
+	}
+	filtered := make([]*v1alpha1.Application, 0, len(apps))
+	for _, app := range apps {
+		if selector.Matches(labels.Set(app.GetLabels())) {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered
+}
+
+// ApplicationEventFilter returns a FilterFunc suitable for cache.FilteringResourceEventHandler, so
+// the controller binary's Application SharedIndexInformer only ever dispatches Add/Update/Delete
+// events for Applications owned by this replica's shard - paired with ApplicationListOptionsFunc's
+// server-side LabelSelector, which narrows the informer's initial List/Watch to the same set, so
+// an unowned Application is never enqueued for reconciliation, let alone reconciled.
+func (m *appStateManager) ApplicationEventFilter() func(obj interface{}) bool {
+	return func(obj interface{}) bool {
+		app, ok := obj.(*v1alpha1.Application)
+		if !ok {
+			tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+			if !ok {
+				return false
+			}
+			app, ok = tombstone.Obj.(*v1alpha1.Application)
+			if !ok {
+				return false
+			}
+		}
+		return m.IsManagedApp(app)
+	}
 }
 
 func (m *appStateManager) getRepoObjs(app *v1alpha1.Application, source v1alpha1.ApplicationSource, appLabelKey, revision string, noCache bool) ([]*unstructured.Unstructured, *apiclient.ManifestResponse, error) {
@@ -234,11 +425,102 @@ func (m *appStateManager) getComparisonSettings(app *appv1.Application) (string,
 	return appLabelKey, resourceOverrides, diffNormalizer, resFilter, nil
 }
 
+// getAppProject resolves the AppProject app.Spec.Project refers to, the same way the rest of the
+// controller does, so project-scoped RBAC checks (IsLiveResourcePermitted, IsGroupKindPermitted)
+// inside CompareAppState see the application's real project instead of a nil pointer.
+func (m *appStateManager) getAppProject(app *v1alpha1.Application) (*appv1.AppProject, error) {
+	return argo.GetAppProject(&app.Spec, m.appclientset, m.namespace)
+}
+
+// hashHealthPluginConfig returns a stable hash of the resource.health.plugins.* entries of argocd-cm,
+// used to tell getHealthPluginEvaluator whether its cached Evaluator is still current. Go's
+// encoding/json marshals map[string]string keys in sorted order, so this is deterministic
+// regardless of map iteration order.
+func hashHealthPluginConfig(exprByGroupKind map[string]string) string {
+	b, err := json.Marshal(exprByGroupKind)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// getHealthPluginEvaluator loads the ResourceReadyChecker plugins declared under the
+// resource.health.plugins.* keys of argocd-cm (same ConfigMap-driven pattern as resourceOverrides)
+// and returns a compiled healthplugin.Evaluator. Operators declare one CEL expression per GroupKind; a
+// nil, empty result means no plugins are configured and callers should fall back to their
+// existing health logic entirely. The compiled Evaluator is cached on m and only rebuilt when
+// those entries change, since CompareAppState calls this once per Application per
+// reconciliation and recompiling every plugin's CEL AST on every call would defeat the point of
+// caching at scale.
+func (m *appStateManager) getHealthPluginEvaluator() (*healthplugin.Evaluator, error) {
+	exprByGroupKind, err := m.settingsMgr.GetResourceHealthPlugins()
+	if err != nil {
+		return nil, err
+	}
+	if len(exprByGroupKind) == 0 {
+		return nil, nil
+	}
+
+	hash := hashHealthPluginConfig(exprByGroupKind)
+	m.healthPluginMu.Lock()
+	defer m.healthPluginMu.Unlock()
+	if m.healthPluginEvaluator != nil && m.healthPluginHash == hash {
+		return m.healthPluginEvaluator, nil
+	}
+
+	plugins := make([]healthplugin.Plugin, 0, len(exprByGroupKind))
+	for groupKind, expr := range exprByGroupKind {
+		// keys are "<group>_<kind>", or a bare "<kind>" for the core group
+		gk := schema.GroupKind{Kind: groupKind}
+		if i := strings.LastIndex(groupKind, "_"); i >= 0 {
+			gk = schema.GroupKind{Group: groupKind[:i], Kind: groupKind[i+1:]}
+		}
+		plugins = append(plugins, healthplugin.Plugin{GroupKind: gk, Expr: expr})
+	}
+	evaluator, err := healthplugin.NewEvaluator(plugins)
+	if err != nil {
+		return nil, err
+	}
+	m.healthPluginEvaluator = evaluator
+	m.healthPluginHash = hash
+	return evaluator, nil
+}
+
+// healthRank orders HealthStatusCode by severity so plugin-produced statuses can be merged with
+// the result of argohealth.SetApplicationHealth by taking the worst of the two, the same
+// precedence SetApplicationHealth itself uses to aggregate per-resource health.
+var healthRank = map[health.HealthStatusCode]int{
+	health.HealthStatusHealthy:     0,
+	health.HealthStatusSuspended:   1,
+	health.HealthStatusProgressing: 2,
+	health.HealthStatusDegraded:    3,
+	health.HealthStatusMissing:     4,
+	health.HealthStatusUnknown:     5,
+}
+
+func worseHealth(a, b health.HealthStatusCode) health.HealthStatusCode {
+	if healthRank[b] > healthRank[a] {
+		return b
+	}
+	return a
+}
+
 // CompareAppState compares application git state to the live app state, using the specified
 // revision and supplied source. If revision or overrides are empty, then compares against
 // revision and overrides in the app spec.
-func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *appv1.AppProject, revision string, source v1alpha1.ApplicationSource, noCache bool, localManifests []string) *comparisonResult {
+func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *appv1.AppProject, revision string, source v1alpha1.ApplicationSource, noCache bool, localManifests []string, checkReadiness bool) *comparisonResult {
 	ts := stats.NewTimingStats()
+
+	// an application outside our shard is owned by another controller replica; skip it entirely
+	// rather than doing wasted repo/live comparison work for it, and return nil rather than a
+	// synthetic "Unknown" result so that callers never persist a status for an app they don't
+	// own - the owning replica's real comparisonResult is the only one that should ever be
+	// written back.
+	if !m.IsManagedApp(app) {
+		return nil
+	}
+
 	appLabelKey, resourceOverrides, diffNormalizer, resFilter, err := m.getComparisonSettings(app)
 	ts.AddCheckpoint("settings_ms")
 
@@ -333,12 +615,28 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *ap
 	ts.AddCheckpoint("live_ms")
 
 	logCtx.Debugf("built managed objects list")
-	// Do the actual comparison
-	diffResults, err := diff.DiffArray(reconciliation.Target, reconciliation.Live, diffNormalizer)
-	if err != nil {
-		diffResults = &diff.DiffResultList{}
-		failedToLoadObjs = true
-		conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
+
+	// A fresh drift snapshot for this exact revision means the independent driftdetector
+	// subsystem already ran DiffArray for us on its own cadence; reuse it instead of diffing
+	// again inline. This only applies when we're comparing against the repo (not ad-hoc
+	// localManifests), the caller allowed cached results, the source wasn't overridden (the
+	// detector always computes against app.Spec.Source, never a syncOp.Source), and the live
+	// object set hasn't moved on from what the snapshot saw - matching revision strings alone
+	// doesn't prove that, since the snapshot can be up to one detector interval stale.
+	var diffResults *diff.DiffResultList
+	if m.driftDetector != nil && !noCache && manifestInfo != nil {
+		if snap, ok := m.driftDetector.Latest(app.Name); ok && reflect.DeepEqual(source, snap.Source) && snap.Revision == manifestInfo.Revision && snapshotLiveMatches(snap, liveObjByKey) {
+			diffResults, _ = mergeSnapshotDiffResults(snap, reconciliation)
+		}
+	}
+	if diffResults == nil {
+		// Do the actual comparison, skipping re-diffing of any (target, live) pair already cached.
+		diffResults, err = m.diffArrayCached(reconciliation.Target, reconciliation.Live, diffNormalizer, app.Spec.Destination.Server)
+		if err != nil {
+			diffResults = &diff.DiffResultList{}
+			failedToLoadObjs = true
+			conditions = append(conditions, v1alpha1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
+		}
 	}
 	ts.AddCheckpoint("diff_ms")
 
@@ -440,6 +738,43 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *ap
 		conditions = append(conditions, appv1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: err.Error(), LastTransitionTime: &now})
 	}
 
+	// User-declared ResourceReadyChecker plugins are evaluated last for the GroupKinds they cover.
+	// A plugin's verdict is folded into both the matching resource's own displayed health (so a
+	// Crossplane/Strimzi resource's entry in the resource tree reflects the plugin, not whatever
+	// the default Lua/unknown health returned) and the app-level rollup, taking the worst of the
+	// two the same way SetApplicationHealth aggregates its own per-resource statuses.
+	if healthEvaluator, evalErr := m.getHealthPluginEvaluator(); evalErr != nil {
+		conditions = append(conditions, appv1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: evalErr.Error(), LastTransitionTime: &now})
+	} else if healthEvaluator != nil && healthStatus != nil {
+		for i := range managedResources {
+			liveObj := managedResources[i].Live
+			if liveObj == nil {
+				continue
+			}
+			pluginStatus, handled, evalErr := healthEvaluator.Evaluate(liveObj)
+			if evalErr != nil {
+				conditions = append(conditions, appv1.ApplicationCondition{Type: v1alpha1.ApplicationConditionComparisonError, Message: evalErr.Error(), LastTransitionTime: &now})
+				continue
+			}
+			if !handled {
+				continue
+			}
+			if existing := resourceSummaries[i].Health; existing == nil || healthRank[pluginStatus] > healthRank[existing.Status] {
+				resourceSummaries[i].Health = &v1alpha1.HealthStatus{Status: pluginStatus}
+			}
+			healthStatus.Status = worseHealth(healthStatus.Status, pluginStatus)
+		}
+	}
+
+	// Comprehensive readiness is evaluated against the same cached live objects used above, so it
+	// costs no extra API calls - but it's still an unstructured->typed conversion pass over every
+	// managed resource, so only pay for it when a caller actually needs to gate on it (i.e.
+	// SyncAppState's Wait=true poll loop), not on every routine comparison.
+	resourcesReady, progressingMessages := true, []string(nil)
+	if checkReadiness {
+		resourcesReady, progressingMessages = readiness.AllReady(GetLiveObjs(managedResources))
+	}
+
 	compRes := comparisonResult{
 		syncStatus:           &syncStatus,
 		healthStatus:         healthStatus,
@@ -447,6 +782,8 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *ap
 		managedResources:     managedResources,
 		reconciliationResult: reconciliation,
 		diffNormalizer:       diffNormalizer,
+		resourcesProgressing: !resourcesReady,
+		progressingMessages:  progressingMessages,
 	}
 	if manifestInfo != nil {
 		compRes.appSourceType = v1alpha1.ApplicationSourceType(manifestInfo.SourceType)
@@ -458,10 +795,171 @@ func (m *appStateManager) CompareAppState(app *v1alpha1.Application, project *ap
 		appv1.ApplicationConditionExcludedResourceWarning: true,
 	})
 	ts.AddCheckpoint("health_ms")
-	compRes.timings = ts.Timings()
+	if m.metricsServer != nil {
+		for phase, duration := range ts.Timings() {
+			m.metricsServer.ObserveComparisonPhaseDuration(phase, duration)
+		}
+	}
 	return &compRes
 }
 
+const (
+	// defaultWaitTimeout bounds how long SyncAppState blocks for readiness when the operation
+	// does not specify its own Wait-Timeout sync option.
+	defaultWaitTimeout = 5 * time.Minute
+	waitPollInterval   = 2 * time.Second
+	waitPollMaxBackoff = 30 * time.Second
+)
+
+// waitForReadiness blocks until every resource in the latest comparison is reported ready by the
+// readiness package, or until timeout elapses, polling comparisonFn (typically a fresh
+// CompareAppState call) with exponential backoff so we don't hammer the live state cache.
+func waitForReadiness(timeout time.Duration, comparisonFn func() *comparisonResult) error {
+	deadline := time.Now().Add(timeout)
+	backoff := waitPollInterval
+	for {
+		res := comparisonFn()
+		if res == nil || !res.resourcesProgressing {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for resources to become ready: %s", timeout, strings.Join(res.progressingMessages, "; "))
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > waitPollMaxBackoff {
+			backoff = waitPollMaxBackoff
+		}
+	}
+}
+
+// SyncAppState applies the result of the most recent comparison to the live cluster via kubectl,
+// then, if the operation carries the "Wait=true" sync option, blocks until the synced resources
+// pass the Helm-v3-style comprehensive readiness check (or the operation's Wait-Timeout elapses)
+// before marking the operation complete.
+func (m *appStateManager) SyncAppState(app *v1alpha1.Application, state *v1alpha1.OperationState) {
+	if state.Operation.Sync == nil {
+		state.Phase = v1alpha1.OperationFailed
+		state.Message = "Invalid operation request: no sync specified"
+		return
+	}
+
+	syncOp := state.Operation.Sync
+	source := app.Spec.Source
+	if syncOp.Source != nil {
+		source = *syncOp.Source
+	}
+
+	project, err := m.getAppProject(app)
+	if err != nil {
+		state.Phase = v1alpha1.OperationFailed
+		state.Message = fmt.Sprintf("Failed to load application project %q: %v", app.Spec.GetProject(), err)
+		return
+	}
+
+	compareResult := m.CompareAppState(app, project, syncOp.Revision, source, false, nil, false)
+	if compareResult == nil {
+		log.WithField("application", app.Name).Warn("skipping sync: application is not owned by this controller replica's shard")
+		return
+	}
+	state.SyncResult = &v1alpha1.SyncOperationResult{
+		Revision: compareResult.syncStatus.Revision,
+		Source:   source,
+	}
+
+	results := make([]v1alpha1.ResourceResult, 0, len(compareResult.managedResources))
+	failures := make([]string, 0)
+	for _, res := range compareResult.managedResources {
+		if res.Hook {
+			continue
+		}
+		if res.Target == nil {
+			// present live but removed from git: prune it, unless the operation didn't ask for
+			// pruning, in which case we report it as out-of-sync-but-skipped rather than silently
+			// dropping it, matching the "sync is additive-only unless Prune=true" contract.
+			if res.Live == nil {
+				continue
+			}
+			if !syncOp.Prune {
+				results = append(results, v1alpha1.ResourceResult{
+					Group:     res.Group,
+					Version:   res.Version,
+					Kind:      res.Kind,
+					Namespace: res.Namespace,
+					Name:      res.Name,
+					Status:    v1alpha1.ResultCodePruneSkipped,
+					Message:   "ignored (requires pruning)",
+				})
+				continue
+			}
+			message, err := m.kubectl.DeleteResource(context.Background(), res.Live, res.Namespace, false)
+			status := v1alpha1.ResultCodePruned
+			if err != nil {
+				status = v1alpha1.ResultCodeSyncFailed
+				failures = append(failures, fmt.Sprintf("%s/%s %s: %v", res.Group, res.Kind, res.Name, err))
+			}
+			results = append(results, v1alpha1.ResourceResult{
+				Group:     res.Group,
+				Version:   res.Version,
+				Kind:      res.Kind,
+				Namespace: res.Namespace,
+				Name:      res.Name,
+				Status:    status,
+				Message:   message,
+			})
+			continue
+		}
+		// res.Namespace is the per-resource namespace computed by sync.Reconcile (empty for
+		// cluster-scoped kinds, the manifest's own namespace when it sets one); applying to the
+		// app-wide destination namespace unconditionally would put cluster-scoped resources and
+		// explicitly-namespaced manifests in the wrong place.
+		message, err := m.kubectl.ApplyResource(context.Background(), res.Target, res.Namespace, false, false)
+		status := v1alpha1.ResultCodeSynced
+		if err != nil {
+			status = v1alpha1.ResultCodeSyncFailed
+			failures = append(failures, fmt.Sprintf("%s/%s %s: %v", res.Group, res.Kind, res.Name, err))
+		}
+		results = append(results, v1alpha1.ResourceResult{
+			Group:     res.Group,
+			Version:   res.Version,
+			Kind:      res.Kind,
+			Namespace: res.Namespace,
+			Name:      res.Name,
+			Status:    status,
+			Message:   message,
+		})
+	}
+	state.SyncResult.Resources = results
+
+	if len(failures) > 0 {
+		state.Phase = v1alpha1.OperationFailed
+		state.Message = strings.Join(failures, "; ")
+		return
+	}
+
+	if syncOp.SyncOptions.HasOption("Wait=true") {
+		timeout := defaultWaitTimeout
+		for _, opt := range syncOp.SyncOptions {
+			if raw := strings.TrimPrefix(opt, "Wait-Timeout="); raw != opt {
+				if parsed, err := time.ParseDuration(raw); err == nil {
+					timeout = parsed
+				}
+			}
+		}
+		err := waitForReadiness(timeout, func() *comparisonResult {
+			return m.CompareAppState(app, project, syncOp.Revision, source, true, nil, true)
+		})
+		if err != nil {
+			state.Phase = v1alpha1.OperationFailed
+			state.Message = err.Error()
+			return
+		}
+	}
+
+	state.Phase = v1alpha1.OperationSucceeded
+	state.Message = "successfully synced"
+}
+
 func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revision string, source v1alpha1.ApplicationSource) error {
 	var nextID int64
 	if len(app.Status.History) > 0 {
@@ -488,7 +986,14 @@ func (m *appStateManager) persistRevisionHistory(app *v1alpha1.Application, revi
 	return err
 }
 
-// NewAppStateManager creates new instance of AppStateManager
+// NewAppStateManager creates new instance of AppStateManager. shardSelector, if non-empty, is
+// parsed as a label selector (e.g. "argocd.argoproj.io/shard=a,!foo/controller") and restricts
+// this manager to Applications whose labels match it, enabling horizontal sharding of the
+// application controller across multiple replicas. The cmd/argocd-application-controller binary
+// is expected to populate shardSelector and driftDetectorWorkers straight from their own CLI
+// flags (e.g. "--shard-selector", "--drift-detector-workers"); driftDetectorWorkers <= 0 disables
+// the drift detector subsystem entirely, preserving today's behavior for callers that don't pass
+// it. driftEvents, if non-nil, receives every DriftEvent the detector produces.
 func NewAppStateManager(
 	db db.ArgoDB,
 	appclientset appclientset.Interface,
@@ -499,8 +1004,16 @@ func NewAppStateManager(
 	liveStateCache statecache.LiveStateCache,
 	projInformer cache.SharedIndexInformer,
 	metricsServer *metrics.MetricsServer,
-) AppStateManager {
-	return &appStateManager{
+	shardSelector string,
+	driftDetectorInterval time.Duration,
+	driftDetectorWorkers int,
+	driftEvents chan driftdetector.DriftEvent,
+) (AppStateManager, error) {
+	selector, err := labels.Parse(shardSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shard selector %q: %v", shardSelector, err)
+	}
+	mgr := &appStateManager{
 		liveStateCache: liveStateCache,
 		db:             db,
 		appclientset:   appclientset,
@@ -510,5 +1023,176 @@ func NewAppStateManager(
 		settingsMgr:    settingsMgr,
 		projInformer:   projInformer,
 		metricsServer:  metricsServer,
+		shardSelector:  selector,
+		diffCache:      diffcache.NewCache(defaultDiffCacheSize),
+	}
+	if driftDetectorWorkers > 0 {
+		go mgr.StartDriftDetector(context.Background(), driftDetectorInterval, driftDetectorWorkers, driftEvents)
+	}
+	return mgr, nil
+}
+
+// StartDriftDetector wires up and runs the independent drift detection subsystem (see
+// controller/driftdetector) on the given interval, using workers goroutines to spread the
+// per-app recompute across the worker pool sized by the --drift-detector-workers flag. It blocks
+// until ctx is cancelled, so callers run it in its own goroutine. events, if non-nil, is fed
+// every DriftEvent produced, for consumption by notifications/UI.
+func (m *appStateManager) StartDriftDetector(ctx context.Context, interval time.Duration, workers int, events chan driftdetector.DriftEvent) {
+	list := func() []string {
+		opts := metav1.ListOptions{}
+		m.ApplicationListOptionsFunc()(&opts)
+		apps, err := m.appclientset.ArgoprojV1alpha1().Applications(m.namespace).List(opts)
+		if err != nil {
+			log.Warnf("drift detector: failed to list applications: %v", err)
+			return nil
+		}
+		ptrs := make([]*v1alpha1.Application, len(apps.Items))
+		for i := range apps.Items {
+			ptrs[i] = &apps.Items[i]
+		}
+		// the LabelSelector above already does this server-side; FilterByShard is a
+		// defense-in-depth re-check in case the API server's label-selector semantics ever
+		// diverge from shardSelector's (e.g. a field selector fallback).
+		owned := FilterByShard(ptrs, m.shardSelector)
+		names := make([]string, len(owned))
+		for i, app := range owned {
+			names[i] = app.Name
+		}
+		return names
 	}
+	detector := driftdetector.NewDetector(interval, workers, list, m.computeDriftSnapshot, events)
+	m.driftDetector = detector
+	detector.Run(ctx)
+}
+
+// snapshotLiveMatches reports whether every live object CompareAppState currently sees was also
+// seen, at the same resourceVersion, by the drift snapshot being considered for reuse. A
+// resourceVersion changes on any mutation of that object, so this is sufficient to detect drift
+// the snapshot couldn't have known about (edits) as well as objects created or deleted since the
+// snapshot was computed (caught by the length check).
+func snapshotLiveMatches(snap *driftdetector.Snapshot, liveObjByKey map[kubeutil.ResourceKey]*unstructured.Unstructured) bool {
+	if len(snap.LiveResourceVersions) != len(liveObjByKey) {
+		return false
+	}
+	for key, live := range liveObjByKey {
+		if live == nil {
+			return false
+		}
+		rv, ok := snap.LiveResourceVersions[key.String()]
+		if !ok || rv != live.GetResourceVersion() {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceIdentityKey returns a stable identity for a (target, live) pair, preferring whichever of
+// the two is present - sync.Reconcile only ever pairs a target with a live object when at least
+// one of them exists. Returns "" if both are nil.
+func resourceIdentityKey(target, live *unstructured.Unstructured) string {
+	obj := target
+	if obj == nil {
+		obj = live
+	}
+	if obj == nil {
+		return ""
+	}
+	return kubeutil.GetResourceKey(obj).String()
+}
+
+// mergeSnapshotDiffResults rebuilds a diff.DiffResultList for this call's reconciliation by
+// looking up each resource's own diff.DiffResult in the snapshot by identity, not position - two
+// independent sync.Reconcile calls are not guaranteed to order their Target/Live slices the same
+// way, since sync.Reconcile is fed a Go map of live objects. ok is false if the snapshot has no
+// entry for a resource that's part of the current reconciliation, in which case the caller must
+// fall back to an inline diff rather than reuse a partial or misaligned result.
+func mergeSnapshotDiffResults(snap *driftdetector.Snapshot, reconciliation sync.ReconciliationResult) (diffs *diff.DiffResultList, ok bool) {
+	results := make([]diff.DiffResult, len(reconciliation.Target))
+	for i, targetObj := range reconciliation.Target {
+		liveObj := reconciliation.Live[i]
+		key := resourceIdentityKey(targetObj, liveObj)
+		result, found := snap.DiffResults[key]
+		if !found {
+			return nil, false
+		}
+		results[i] = result
+	}
+	return &diff.DiffResultList{Diffs: results}, true
+}
+
+// computeDriftSnapshot performs a standalone getRepoObjs+DiffArray pass for appName, independent
+// of any in-flight CompareAppState call, and packages the result as a driftdetector.Snapshot.
+func (m *appStateManager) computeDriftSnapshot(ctx context.Context, appName string) (*driftdetector.Snapshot, error) {
+	app, err := m.appclientset.ArgoprojV1alpha1().Applications(m.namespace).Get(appName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	appLabelKey, _, diffNormalizer, _, err := m.getComparisonSettings(app)
+	if err != nil {
+		return nil, err
+	}
+	targetObjs, manifestInfo, err := m.getRepoObjs(app, app.Spec.Source, appLabelKey, "", false)
+	if err != nil {
+		return nil, err
+	}
+	liveObjByKey, err := m.liveStateCache.GetManagedLiveObjs(app, targetObjs)
+	if err != nil {
+		return nil, err
+	}
+	reconciliation := sync.Reconcile(targetObjs, liveObjByKey, app.Spec.Destination.Server, app.Spec.Destination.Namespace, m.liveStateCache)
+	diffResults, err := diff.DiffArray(reconciliation.Target, reconciliation.Live, diffNormalizer)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]driftdetector.DriftEvent, 0)
+	liveResourceVersions := make(map[string]string, len(reconciliation.Live))
+	diffByKey := make(map[string]diff.DiffResult, len(reconciliation.Target))
+	for i, targetObj := range reconciliation.Target {
+		liveObj := reconciliation.Live[i]
+		if liveObj != nil {
+			liveResourceVersions[kubeutil.GetResourceKey(liveObj).String()] = liveObj.GetResourceVersion()
+		}
+		if key := resourceIdentityKey(targetObj, liveObj); key != "" && i < len(diffResults.Diffs) {
+			diffByKey[key] = diffResults.Diffs[i]
+		}
+		obj := liveObj
+		if obj == nil {
+			obj = targetObj
+		}
+		if obj == nil {
+			continue
+		}
+		var driftType driftdetector.DriftType
+		switch {
+		case targetObj == nil:
+			driftType = driftdetector.DriftRemoved
+		case liveObj == nil:
+			driftType = driftdetector.DriftAdded
+		case i < len(diffResults.Diffs) && diffResults.Diffs[i].Modified:
+			driftType = driftdetector.DriftModified
+		default:
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		events = append(events, driftdetector.DriftEvent{
+			AppName:   appName,
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Type:      driftType,
+		})
+	}
+
+	return &driftdetector.Snapshot{
+		Revision:             manifestInfo.Revision,
+		Source:               app.Spec.Source,
+		LiveResourceVersions: liveResourceVersions,
+		ComputedAt:           time.Now(),
+		Reconciliation:       reconciliation,
+		DiffResults:          diffByKey,
+		Events:               events,
+	}, nil
 }
@@ -0,0 +1,36 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/argoproj/argo-cd/common"
+)
+
+// resourceHealthPluginsKeyPrefix is the argocd-cm key prefix operators use to declare a CEL
+// health expression for a GroupKind, e.g. "resource.health.plugins.example.com_Widget". It
+// mirrors the resource.customizations.<group_kind> convention already used for the Lua
+// diff/health overrides, under its own prefix since these expressions are CEL, not Lua.
+const resourceHealthPluginsKeyPrefix = "resource.health.plugins."
+
+// GetResourceHealthPlugins returns the CEL health expression declared for each GroupKind in the
+// argocd-cm ConfigMap, keyed by the "<group>_<kind>" part of the key that follows the prefix,
+// the same ConfigMap-driven pattern as GetResourceOverrides. A nil/empty result means no plugins
+// are configured and callers should fall back to their existing health logic entirely.
+func (mgr *SettingsManager) GetResourceHealthPlugins() (map[string]string, error) {
+	argoCDCM, err := mgr.clientset.CoreV1().ConfigMaps(mgr.namespace).Get(common.ArgoCDConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", common.ArgoCDConfigMapName, err)
+	}
+	plugins := make(map[string]string)
+	for key, expr := range argoCDCM.Data {
+		groupKind := strings.TrimPrefix(key, resourceHealthPluginsKeyPrefix)
+		if groupKind == key || groupKind == "" || expr == "" {
+			continue
+		}
+		plugins[groupKind] = expr
+	}
+	return plugins, nil
+}